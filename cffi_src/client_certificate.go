@@ -0,0 +1,113 @@
+package tls_client_cffi_src
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	tls "github.com/bogdanfinn/utls"
+)
+
+// ClientCertificate presents a client certificate for mTLS, optionally
+// pinning a custom trust store instead of the system roots.
+type ClientCertificate struct {
+	Cert        string   `json:"cert"`
+	Key         string   `json:"key"`
+	KeyPassword string   `json:"keyPassword"`
+	RootCAs     []string `json:"rootCAs"`
+}
+
+var certCacheLock = sync.Mutex{}
+var certCache = make(map[string]tls.Certificate)
+
+var rootCAsCacheLock = sync.Mutex{}
+var rootCAsCache = make(map[string]*x509.CertPool)
+
+func parseClientCertificate(clientCertificate *ClientCertificate) (tls.Certificate, error) {
+	fingerprint := fingerprintOf(clientCertificate.Cert, clientCertificate.Key, clientCertificate.KeyPassword)
+
+	certCacheLock.Lock()
+	defer certCacheLock.Unlock()
+
+	if cert, ok := certCache[fingerprint]; ok {
+		return cert, nil
+	}
+
+	keyPEM := []byte(clientCertificate.Key)
+
+	if clientCertificate.KeyPassword != "" {
+		decryptedKeyPEM, err := decryptPEMKey(keyPEM, clientCertificate.KeyPassword)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+
+		keyPEM = decryptedKeyPEM
+	}
+
+	cert, err := tls.X509KeyPair([]byte(clientCertificate.Cert), keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse client certificate/key pair: %w", err)
+	}
+
+	certCache[fingerprint] = cert
+
+	return cert, nil
+}
+
+func parseRootCAs(rootCAs []string) (*x509.CertPool, error) {
+	fingerprint := fingerprintOf(rootCAs...)
+
+	rootCAsCacheLock.Lock()
+	defer rootCAsCacheLock.Unlock()
+
+	if pool, ok := rootCAsCache[fingerprint]; ok {
+		return pool, nil
+	}
+
+	pool := x509.NewCertPool()
+
+	for _, bundle := range rootCAs {
+		if !pool.AppendCertsFromPEM([]byte(bundle)) {
+			return nil, fmt.Errorf("failed to parse root CA bundle")
+		}
+	}
+
+	rootCAsCache[fingerprint] = pool
+
+	return pool, nil
+}
+
+// decryptPEMKey decrypts a password-protected PEM-encoded private key block.
+func decryptPEMKey(keyPEM []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing the client key")
+	}
+
+	//nolint:staticcheck // x509.IsEncryptedPEMBlock/DecryptPEMBlock are deprecated but still the
+	// only stdlib path for legacy encrypted PKCS#1 PEM keys, which is what KeyPassword targets.
+	if !x509.IsEncryptedPEMBlock(block) {
+		return keyPEM, nil
+	}
+
+	decrypted, err := x509.DecryptPEMBlock(block, []byte(password))
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: decrypted}), nil
+}
+
+func fingerprintOf(parts ...string) string {
+	hash := sha256.New()
+
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
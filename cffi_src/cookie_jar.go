@@ -0,0 +1,33 @@
+package tls_client_cffi_src
+
+import (
+	"sync"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/bogdanfinn/fhttp/cookiejar"
+)
+
+// cookieJars holds one cookie jar per session, shared across the session's
+// plain client in `clients` and any per-proxy clients in `proxyChainClients`,
+// so cookies collected through one dialer are still there after the session
+// rotates to a different proxy or falls back to the direct client.
+var cookieJarsLock = sync.Mutex{}
+var cookieJars = make(map[string]http.CookieJar)
+
+func getOrCreateCookieJar(sessionId string) (http.CookieJar, error) {
+	cookieJarsLock.Lock()
+	defer cookieJarsLock.Unlock()
+
+	if jar, ok := cookieJars[sessionId]; ok {
+		return jar, nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cookieJars[sessionId] = jar
+
+	return jar, nil
+}
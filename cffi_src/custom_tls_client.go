@@ -0,0 +1,21 @@
+package tls_client_cffi_src
+
+type CustomTlsClient struct {
+	Ja3String         string          `json:"ja3String"`
+	H2Settings        map[int]uint32  `json:"h2Settings"`
+	H2SettingsOrder   []int           `json:"h2SettingsOrder"`
+	PseudoHeaderOrder []string        `json:"pseudoHeaderOrder"`
+	ConnectionFlow    uint32          `json:"connectionFlow"`
+	PriorityFrames    []PriorityFrame `json:"priorityFrames"`
+}
+
+type PriorityFrame struct {
+	StreamID      uint32             `json:"streamID"`
+	PriorityParam PriorityFrameParam `json:"priorityParam"`
+}
+
+type PriorityFrameParam struct {
+	StreamDep uint32 `json:"streamDep"`
+	Exclusive bool   `json:"exclusive"`
+	Weight    uint8  `json:"weight"`
+}
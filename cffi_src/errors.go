@@ -0,0 +1,44 @@
+package tls_client_cffi_src
+
+import (
+	"context"
+	"errors"
+)
+
+type TLSClientErrorCode string
+
+const (
+	ErrCodeGeneric   TLSClientErrorCode = "generic"
+	ErrCodeCancelled TLSClientErrorCode = "request_cancelled"
+	ErrCodeTimeout   TLSClientErrorCode = "request_timeout"
+)
+
+type TLSClientError struct {
+	err  error
+	Code TLSClientErrorCode
+}
+
+func NewTLSClientError(err error) *TLSClientError {
+	return &TLSClientError{
+		err:  err,
+		Code: classifyError(err),
+	}
+}
+
+func (e *TLSClientError) Error() string {
+	return e.err.Error()
+}
+
+// classifyError distinguishes a cancelled/timed-out request context from a
+// plain IO or protocol error, so callers can tell the two apart without
+// string-matching Error().
+func classifyError(err error) TLSClientErrorCode {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrCodeCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrCodeTimeout
+	default:
+		return ErrCodeGeneric
+	}
+}
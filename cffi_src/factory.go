@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"reflect"
 	"sync"
+	"time"
 
 	http "github.com/bogdanfinn/fhttp"
 	"github.com/bogdanfinn/fhttp/http2"
@@ -15,6 +18,7 @@ import (
 
 var clientsLock = sync.Mutex{}
 var clients = make(map[string]tls_client.HttpClient)
+var clientTransportOptions = make(map[string]*TransportOptions)
 
 func GetTlsClientFromInput(requestInput RequestInput) (tls_client.HttpClient, string, *TLSClientError) {
 	sessionId := requestInput.SessionId
@@ -83,7 +87,40 @@ func BuildRequest(input RequestInput) (*http.Request, *TLSClientError) {
 	return tlsReq, nil
 }
 
-func BuildResponse(sessionId string, resp *http.Response, cookies []*http.Cookie) (Response, *TLSClientError) {
+// SendRequest builds the request like BuildRequest but also attaches a
+// cancellable, independently-timeboxed context (via RequestTimeoutMilliseconds)
+// and returns the RequestId it was registered under, so the caller can abort
+// it in-flight with CancelRequest. Callers must call ReleaseRequestContext
+// once the request has completed, whether it succeeded, failed, or was
+// cancelled.
+func SendRequest(input RequestInput) (*http.Request, string, *TLSClientError) {
+	tlsReq, clientErr := BuildRequest(input)
+	if clientErr != nil {
+		return nil, "", clientErr
+	}
+
+	ctx, requestId := newRequestContext(input.RequestTimeoutMilliseconds)
+
+	return tlsReq.WithContext(ctx), requestId, nil
+}
+
+func BuildResponse(sessionId string, resp *http.Response, cookies []*http.Cookie, streamOutput bool, retryOutcome RetryOutcome) (Response, *TLSClientError) {
+	if streamOutput {
+		streamId := registerStream(resp)
+
+		response := Response{
+			SessionId:      sessionId,
+			Status:         resp.StatusCode,
+			Headers:        resp.Header,
+			Cookies:        cookiesToMap(cookies),
+			StreamId:       &streamId,
+			Attempts:       retryOutcome.Attempts,
+			FinalBackoffMs: retryOutcome.FinalBackoffMs,
+		}
+
+		return response, nil
+	}
+
 	defer resp.Body.Close()
 
 	respBodyBytes, err := ioutil.ReadAll(resp.Body)
@@ -93,11 +130,13 @@ func BuildResponse(sessionId string, resp *http.Response, cookies []*http.Cookie
 	}
 
 	response := Response{
-		SessionId: sessionId,
-		Status:    resp.StatusCode,
-		Body:      string(respBodyBytes),
-		Headers:   resp.Header,
-		Cookies:   cookiesToMap(cookies),
+		SessionId:      sessionId,
+		Status:         resp.StatusCode,
+		Body:           string(respBodyBytes),
+		Attempts:       retryOutcome.Attempts,
+		FinalBackoffMs: retryOutcome.FinalBackoffMs,
+		Headers:        resp.Header,
+		Cookies:        cookiesToMap(cookies),
 	}
 
 	return response, nil
@@ -110,9 +149,27 @@ func getTlsClient(requestInput RequestInput, sessionId string) (tls_client.HttpC
 	tlsClientIdentifier := requestInput.TLSClientIdentifier
 	proxyUrl := requestInput.ProxyUrl
 
+	if len(requestInput.ProxyChain) > 0 || (proxyUrl != nil && isSocksProxyUrl(*proxyUrl)) {
+		return getOrBuildProxyChainClient(requestInput, sessionId, tlsClientIdentifier)
+	}
+
 	client, ok := clients[sessionId]
 
 	if ok {
+		if transportOptionsChanged(sessionId, requestInput.TransportOptions) {
+			client.CloseIdleConnections()
+
+			rebuiltClient, err := buildTlsClient(requestInput, sessionId, tlsClientIdentifier, proxyUrl, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rebuild client out of request input: %w", err)
+			}
+
+			clients[sessionId] = rebuiltClient
+			clientTransportOptions[sessionId] = requestInput.TransportOptions
+
+			return rebuiltClient, nil
+		}
+
 		modifiedClient, changed, err := handleModification(client, proxyUrl, requestInput.FollowRedirects)
 		if err != nil {
 			return nil, fmt.Errorf("failed to modify existing client: %w", err)
@@ -125,6 +182,25 @@ func getTlsClient(requestInput RequestInput, sessionId string) (tls_client.HttpC
 		return modifiedClient, nil
 	}
 
+	tlsClient, err := buildTlsClient(requestInput, sessionId, tlsClientIdentifier, proxyUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	clients[sessionId] = tlsClient
+	clientTransportOptions[sessionId] = requestInput.TransportOptions
+
+	return tlsClient, nil
+}
+
+// transportOptionsChanged reports whether requestInput asks for different
+// transport tuning than what the cached client for sessionId was built
+// with, so getTlsClient knows to rebuild rather than reuse the client.
+func transportOptionsChanged(sessionId string, transportOptions *TransportOptions) bool {
+	return !reflect.DeepEqual(clientTransportOptions[sessionId], transportOptions)
+}
+
+func buildTlsClient(requestInput RequestInput, sessionId string, tlsClientIdentifier string, proxyUrl *string, dialContext DialContextFunc) (tls_client.HttpClient, error) {
 	var clientProfile tls_client.ClientProfile
 
 	if requestInput.CustomTlsClient != nil {
@@ -147,9 +223,15 @@ func getTlsClient(requestInput RequestInput, sessionId string) (tls_client.HttpC
 		timeoutSeconds = requestInput.TimeoutSeconds
 	}
 
+	cookieJar, err := getOrCreateCookieJar(sessionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
 	options := []tls_client.HttpClientOption{
 		tls_client.WithTimeout(timeoutSeconds),
 		tls_client.WithClientProfile(clientProfile),
+		tls_client.WithCookieJar(cookieJar),
 	}
 
 	if !requestInput.FollowRedirects {
@@ -160,17 +242,87 @@ func getTlsClient(requestInput RequestInput, sessionId string) (tls_client.HttpC
 		options = append(options, tls_client.WithInsecureSkipVerify())
 	}
 
-	proxy := proxyUrl
+	if dialContext != nil {
+		options = append(options, tls_client.WithDialContext(dialContext))
+	} else if proxyUrl != nil && *proxyUrl != "" {
+		options = append(options, tls_client.WithProxyUrl(*proxyUrl))
+	}
 
-	if proxy != nil && *proxy != "" {
-		options = append(options, tls_client.WithProxyUrl(*proxy))
+	transportOptions, err := transportAndCertificateOptions(requestInput)
+	if err != nil {
+		return nil, fmt.Errorf("can not build http client out of transport/client certificate information: %w", err)
 	}
 
-	tlsClient, err := tls_client.NewHttpClient(tls_client.NewNoopLogger(), options...)
+	options = append(options, transportOptions...)
 
-	clients[sessionId] = tlsClient
+	return tls_client.NewHttpClient(tls_client.NewNoopLogger(), options...)
+}
+
+// transportAndCertificateOptions merges TransportOptions and
+// ClientCertificate into a single tls_client.TransportOptions (the library
+// only accepts one per client, via tls_client.WithTransportOptions), plus
+// any dialer/protocol tweaks that sit outside of it.
+func transportAndCertificateOptions(requestInput RequestInput) ([]tls_client.HttpClientOption, error) {
+	var options []tls_client.HttpClientOption
+
+	libTransportOptions := &tls_client.TransportOptions{}
+	hasTransportOptions := false
+
+	if transportOptions := requestInput.TransportOptions; transportOptions != nil {
+		hasTransportOptions = true
+
+		libTransportOptions.DisableKeepAlives = transportOptions.DisableKeepAlives
+		libTransportOptions.MaxIdleConns = transportOptions.MaxIdleConns
+		libTransportOptions.MaxIdleConnsPerHost = transportOptions.MaxIdleConnsPerHost
+
+		if transportOptions.IdleConnTimeoutSeconds != 0 {
+			libTransportOptions.IdleConnTimeout = time.Duration(transportOptions.IdleConnTimeoutSeconds) * time.Second
+		}
+
+		if transportOptions.DialTimeoutSeconds != 0 || transportOptions.KeepaliveSeconds != 0 {
+			dialer := net.Dialer{}
+
+			if transportOptions.DialTimeoutSeconds != 0 {
+				dialer.Timeout = time.Duration(transportOptions.DialTimeoutSeconds) * time.Second
+			}
+
+			if transportOptions.KeepaliveSeconds != 0 {
+				dialer.KeepAlive = time.Duration(transportOptions.KeepaliveSeconds) * time.Second
+			}
+
+			options = append(options, tls_client.WithDialer(dialer))
+		}
+
+		if transportOptions.ForceHTTP1 {
+			options = append(options, tls_client.WithForceHttp1())
+		}
+	}
+
+	if clientCertificate := requestInput.ClientCertificate; clientCertificate != nil {
+		hasTransportOptions = true
+
+		cert, err := parseClientCertificate(clientCertificate)
+		if err != nil {
+			return nil, err
+		}
+
+		libTransportOptions.Certificates = append(libTransportOptions.Certificates, cert)
+
+		if len(clientCertificate.RootCAs) > 0 {
+			rootCAs, err := parseRootCAs(clientCertificate.RootCAs)
+			if err != nil {
+				return nil, err
+			}
+
+			libTransportOptions.RootCAs = rootCAs
+		}
+	}
+
+	if hasTransportOptions {
+		options = append([]tls_client.HttpClientOption{tls_client.WithTransportOptions(libTransportOptions)}, options...)
+	}
 
-	return tlsClient, err
+	return options, nil
 }
 
 func getCustomTlsClientProfile(customClientDefinition *CustomTlsClient) (tls.ClientHelloID, map[http2.SettingID]uint32, []http2.SettingID, []string, uint32, []http2.Priority, error) {
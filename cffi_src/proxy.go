@@ -0,0 +1,164 @@
+package tls_client_cffi_src
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	tls_client "github.com/bogdanfinn/tls-client"
+	"golang.org/x/net/proxy"
+)
+
+// DialContextFunc matches net.Dialer.DialContext / http.Transport.DialContext
+// so a SOCKS5 (chain) dialer can be installed in place of tls_client's
+// built-in http(s) proxy support.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// proxyChainClients caches, per session, a client per distinct proxy chain
+// override so a request with a different ProxyChain/socks proxy can reuse a
+// pooled connection without evicting the session's default (non-proxy or
+// http(s)-proxy) client. proxyChainTransportOptions tracks what each of
+// those cached clients was last built with, mirroring clientTransportOptions,
+// so a later change to TransportOptions still triggers a rebuild here too.
+var proxyChainClientsLock = sync.Mutex{}
+var proxyChainClients = make(map[string]map[string]tls_client.HttpClient)
+var proxyChainTransportOptions = make(map[string]map[string]*TransportOptions)
+
+func isSocksProxyUrl(proxyUrl string) bool {
+	return strings.HasPrefix(proxyUrl, "socks5://") || strings.HasPrefix(proxyUrl, "socks5h://")
+}
+
+// proxyChainKey identifies the proxy override in effect for a request, used
+// to key proxyChainClients so the same chain reuses the same client.
+func proxyChainKey(proxyUrl *string, chain []string) string {
+	if len(chain) > 0 {
+		return strings.Join(chain, ">")
+	}
+
+	if proxyUrl != nil {
+		return *proxyUrl
+	}
+
+	return ""
+}
+
+func getOrBuildProxyChainClient(requestInput RequestInput, sessionId string, tlsClientIdentifier string) (tls_client.HttpClient, error) {
+	chain := requestInput.ProxyChain
+	if len(chain) == 0 {
+		chain = []string{*requestInput.ProxyUrl}
+	}
+
+	key := proxyChainKey(requestInput.ProxyUrl, requestInput.ProxyChain)
+
+	proxyChainClientsLock.Lock()
+	defer proxyChainClientsLock.Unlock()
+
+	sessionProxyClients, ok := proxyChainClients[sessionId]
+	if !ok {
+		sessionProxyClients = make(map[string]tls_client.HttpClient)
+		proxyChainClients[sessionId] = sessionProxyClients
+	}
+
+	sessionTransportOptions, ok := proxyChainTransportOptions[sessionId]
+	if !ok {
+		sessionTransportOptions = make(map[string]*TransportOptions)
+		proxyChainTransportOptions[sessionId] = sessionTransportOptions
+	}
+
+	if client, ok := sessionProxyClients[key]; ok {
+		if !reflect.DeepEqual(sessionTransportOptions[key], requestInput.TransportOptions) {
+			client.CloseIdleConnections()
+
+			rebuiltClient, err := buildProxyChainClient(requestInput, sessionId, tlsClientIdentifier, chain)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rebuild proxy chain client: %w", err)
+			}
+
+			sessionProxyClients[key] = rebuiltClient
+			sessionTransportOptions[key] = requestInput.TransportOptions
+
+			return rebuiltClient, nil
+		}
+
+		modifiedClient, changed, err := handleModification(client, nil, requestInput.FollowRedirects)
+		if err != nil {
+			return nil, fmt.Errorf("failed to modify existing proxy chain client: %w", err)
+		}
+
+		if changed {
+			sessionProxyClients[key] = modifiedClient
+		}
+
+		return modifiedClient, nil
+	}
+
+	client, err := buildProxyChainClient(requestInput, sessionId, tlsClientIdentifier, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionProxyClients[key] = client
+	sessionTransportOptions[key] = requestInput.TransportOptions
+
+	return client, nil
+}
+
+func buildProxyChainClient(requestInput RequestInput, sessionId string, tlsClientIdentifier string, chain []string) (tls_client.HttpClient, error) {
+	dialer, err := buildProxyChainDialer(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build proxy chain dialer: %w", err)
+	}
+
+	client, err := buildTlsClient(requestInput, sessionId, tlsClientIdentifier, nil, dialContextFromDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build client out of request input: %w", err)
+	}
+
+	return client, nil
+}
+
+// buildProxyChainDialer composes a proxy.Dialer out of a chain of proxy
+// URLs, each hop dialing through the previous one, so requests traverse
+// them in order.
+func buildProxyChainDialer(chain []string) (proxy.Dialer, error) {
+	var dialer proxy.Dialer = proxy.Direct
+
+	for i := 0; i < len(chain); i++ {
+		nextDialer, err := proxyDialerFromUrl(chain[i], dialer)
+		if err != nil {
+			return nil, err
+		}
+
+		dialer = nextDialer
+	}
+
+	return dialer, nil
+}
+
+func proxyDialerFromUrl(rawUrl string, forward proxy.Dialer) (proxy.Dialer, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy url %q: %w", rawUrl, err)
+	}
+
+	switch parsedUrl.Scheme {
+	case "socks5", "socks5h":
+		return proxy.FromURL(parsedUrl, forward)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, only socks5/socks5h is supported in a proxy chain", parsedUrl.Scheme)
+	}
+}
+
+func dialContextFromDialer(dialer proxy.Dialer) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+			return contextDialer.DialContext(ctx, network, addr)
+		}
+
+		return dialer.Dial(network, addr)
+	}
+}
@@ -0,0 +1,70 @@
+package tls_client_cffi_src
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var requestContextsLock = sync.Mutex{}
+var requestContexts = make(map[string]context.CancelFunc)
+
+// newRequestContext derives a per-request, cancellable context independent
+// from the session's client-level timeout, and registers its cancel func
+// under a fresh RequestId so CancelRequest can abort it mid-flight.
+func newRequestContext(requestTimeoutMilliseconds int) (context.Context, string) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	if requestTimeoutMilliseconds > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(requestTimeoutMilliseconds)*time.Millisecond)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	requestId := uuid.New().String()
+
+	requestContextsLock.Lock()
+	requestContexts[requestId] = cancel
+	requestContextsLock.Unlock()
+
+	return ctx, requestId
+}
+
+// ReleaseRequestContext drops the bookkeeping for a finished request and
+// releases its context's resources. Callers should call this once the
+// request (successful or not) has completed. Safe to call more than once.
+func ReleaseRequestContext(requestId string) {
+	cancel, ok := popRequestCancel(requestId)
+	if ok {
+		cancel()
+	}
+}
+
+// CancelRequest aborts an in-flight request by RequestId, cancelling its
+// http.Request's context so the round trip returns early.
+func CancelRequest(requestId string) *TLSClientError {
+	cancel, ok := popRequestCancel(requestId)
+	if !ok {
+		return NewTLSClientError(fmt.Errorf("request %s not found", requestId))
+	}
+
+	cancel()
+
+	return nil
+}
+
+func popRequestCancel(requestId string) (context.CancelFunc, bool) {
+	requestContextsLock.Lock()
+	defer requestContextsLock.Unlock()
+
+	cancel, ok := requestContexts[requestId]
+	if ok {
+		delete(requestContexts, requestId)
+	}
+
+	return cancel, ok
+}
@@ -0,0 +1,56 @@
+package tls_client_cffi_src
+
+type RequestInput struct {
+	SessionId           *string           `json:"sessionId"`
+	TLSClientIdentifier string            `json:"tlsClientIdentifier"`
+	CustomTlsClient     *CustomTlsClient  `json:"customTlsClient"`
+	FollowRedirects     bool              `json:"followRedirects"`
+	InsecureSkipVerify  bool              `json:"insecureSkipVerify"`
+	TimeoutSeconds      int               `json:"timeoutSeconds"`
+	ProxyUrl            *string           `json:"proxyUrl"`
+	RequestUrl          string            `json:"requestUrl"`
+	RequestMethod       string            `json:"requestMethod"`
+	RequestBody         *string           `json:"requestBody"`
+	Headers             map[string]string `json:"headers"`
+	HeaderOrder         []string          `json:"headerOrder"`
+
+	// StreamOutput keeps the response body open instead of buffering it into
+	// Response.Body, so large downloads can be pulled through the CFFI
+	// boundary in chunks via ReadFromStream instead of all at once.
+	StreamOutput bool `json:"streamOutput"`
+
+	// TransportOptions tunes the underlying dialer/transport of the cached
+	// client for this session. Changing any of these on a later request for
+	// the same session forces the client to be rebuilt.
+	TransportOptions *TransportOptions `json:"transportOptions"`
+
+	// ProxyChain hops through multiple proxies in order, e.g. a socks5
+	// proxy followed by another. When set it takes precedence over
+	// ProxyUrl. Every hop must use the socks5:// or socks5h:// (remote DNS
+	// resolution) scheme; an http(s) entry in the chain is rejected.
+	ProxyChain []string `json:"proxyChain"`
+
+	// ClientCertificate presents a client certificate for mTLS, alongside
+	// the existing ClientHello spoofing.
+	ClientCertificate *ClientCertificate `json:"clientCertificate"`
+
+	// RequestTimeoutMilliseconds caps this single request independently
+	// from the session-level TimeoutSeconds, so a long-lived session
+	// client can still have individual calls cut short.
+	RequestTimeoutMilliseconds int `json:"requestTimeoutMilliseconds"`
+
+	// RetryPolicy retries this request on a retryable status code or
+	// transient network error. Leave nil to disable retries; must be nil
+	// when StreamOutput is set since a streamed body can't be replayed.
+	RetryPolicy *RetryPolicy `json:"retryPolicy"`
+}
+
+type TransportOptions struct {
+	DialTimeoutSeconds     int  `json:"dialTimeoutSeconds"`
+	KeepaliveSeconds       int  `json:"keepaliveSeconds"`
+	IdleConnTimeoutSeconds int  `json:"idleConnTimeoutSeconds"`
+	MaxIdleConns           int  `json:"maxIdleConns"`
+	MaxIdleConnsPerHost    int  `json:"maxIdleConnsPerHost"`
+	DisableKeepAlives      bool `json:"disableKeepAlives"`
+	ForceHTTP1             bool `json:"forceHTTP1"`
+}
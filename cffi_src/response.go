@@ -0,0 +1,21 @@
+package tls_client_cffi_src
+
+type Response struct {
+	SessionId string              `json:"sessionId"`
+	Status    int                 `json:"status"`
+	Body      string              `json:"body"`
+	Headers   map[string][]string `json:"headers"`
+	Cookies   map[string]string   `json:"cookies"`
+
+	// StreamId is set instead of Body when the request was made with
+	// StreamOutput, so the caller can pull the body incrementally through
+	// ReadFromStream and release it with CloseStream.
+	StreamId *string `json:"streamId,omitempty"`
+
+	// Attempts and FinalBackoffMs report how many times the request was
+	// tried and the backoff before the last attempt, as passed to
+	// BuildResponse in its RetryOutcome. Without a RetryPolicy,
+	// DoRequestWithRetry reports 1 attempt and no backoff.
+	Attempts       int   `json:"attempts,omitempty"`
+	FinalBackoffMs int64 `json:"finalBackoffMs,omitempty"`
+}
@@ -0,0 +1,159 @@
+package tls_client_cffi_src
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	tls_client "github.com/bogdanfinn/tls-client"
+)
+
+// RetryPolicy configures automatic retries for a single request. The
+// request body must already be fully buffered (as BuildRequest does via
+// bytes.NewBuffer) since a streaming body cannot be replayed -- requests
+// using StreamOutput should leave RetryPolicy nil.
+type RetryPolicy struct {
+	MaxRetries        int     `json:"maxRetries"`
+	InitialBackoffMs  int     `json:"initialBackoffMs"`
+	MaxBackoffMs      int     `json:"maxBackoffMs"`
+	BackoffMultiplier float64 `json:"backoffMultiplier"`
+	JitterFraction    float64 `json:"jitterFraction"`
+	RetryStatusCodes  []int   `json:"retryStatusCodes"`
+}
+
+var defaultRetryStatusCodes = []int{408, 429, 500, 502, 503, 504}
+
+// RetryOutcome reports how many attempts DoRequestWithRetry made and the
+// backoff it last slept for, so it can be surfaced on the Response for
+// observability.
+type RetryOutcome struct {
+	Attempts       int
+	FinalBackoffMs int64
+}
+
+// DoRequestWithRetry issues req via client, retrying on a retryable status
+// code (per retryPolicy.RetryStatusCodes) or a transient network error,
+// sleeping an exponential backoff with jitter between attempts. It honors a
+// Retry-After header on 429/503 responses, overriding the computed backoff.
+func DoRequestWithRetry(client tls_client.HttpClient, req *http.Request, retryPolicy *RetryPolicy) (*http.Response, RetryOutcome, error) {
+	if retryPolicy == nil {
+		resp, err := client.Do(req)
+		return resp, RetryOutcome{Attempts: 1}, err
+	}
+
+	policy := withRetryDefaults(*retryPolicy)
+
+	var resp *http.Response
+	var err error
+	var lastBackoff time.Duration
+	attempts := 0
+
+	for {
+		resp, err = client.Do(req)
+		attempts++
+
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			break
+		}
+
+		retryable := err != nil || isRetryableStatus(resp.StatusCode, policy.RetryStatusCodes)
+		if !retryable || attempts > policy.MaxRetries {
+			break
+		}
+
+		backoff := backoffFor(resp, attempts-1, policy)
+		lastBackoff = backoff
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if req.GetBody != nil {
+			freshBody, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				err = bodyErr
+				break
+			}
+
+			req.Body = freshBody
+		}
+
+		time.Sleep(backoff)
+	}
+
+	return resp, RetryOutcome{Attempts: attempts, FinalBackoffMs: lastBackoff.Milliseconds()}, err
+}
+
+func withRetryDefaults(policy RetryPolicy) RetryPolicy {
+	if policy.BackoffMultiplier == 0 {
+		policy.BackoffMultiplier = 2.0
+	}
+
+	if len(policy.RetryStatusCodes) == 0 {
+		policy.RetryStatusCodes = defaultRetryStatusCodes
+	}
+
+	return policy
+}
+
+func isRetryableStatus(status int, retryStatusCodes []int) bool {
+	for _, code := range retryStatusCodes {
+		if code == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoffFor computes the delay before the next attempt, honoring a
+// Retry-After header on the previous response when present.
+func backoffFor(resp *http.Response, attempt int, policy RetryPolicy) time.Duration {
+	if resp != nil {
+		if retryAfter, ok := retryAfterDuration(resp); ok {
+			return retryAfter
+		}
+	}
+
+	backoffMs := float64(policy.InitialBackoffMs) * math.Pow(policy.BackoffMultiplier, float64(attempt))
+
+	if policy.MaxBackoffMs > 0 && backoffMs > float64(policy.MaxBackoffMs) {
+		backoffMs = float64(policy.MaxBackoffMs)
+	}
+
+	if policy.JitterFraction > 0 {
+		jitter := backoffMs * policy.JitterFraction
+		backoffMs += (rand.Float64()*2 - 1) * jitter
+
+		if backoffMs < 0 {
+			backoffMs = 0
+		}
+	}
+
+	return time.Duration(backoffMs) * time.Millisecond
+}
+
+func retryAfterDuration(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	retryAfter := resp.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(retryAfter); err == nil {
+		if until := time.Until(at); until > 0 {
+			return until, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package tls_client_cffi_src
+
+import (
+	"testing"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestBackoffFor(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoffMs:  100,
+		MaxBackoffMs:      1000,
+		BackoffMultiplier: 2.0,
+	}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		expected time.Duration
+	}{
+		{"first attempt", 0, 100 * time.Millisecond},
+		{"second attempt", 1, 200 * time.Millisecond},
+		{"third attempt", 2, 400 * time.Millisecond},
+		{"capped at max backoff", 5, 1000 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backoffFor(nil, tt.attempt, policy)
+			if got != tt.expected {
+				t.Errorf("backoffFor(attempt=%d) = %v, want %v", tt.attempt, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBackoffForAppliesJitterWithinBounds(t *testing.T) {
+	policy := RetryPolicy{InitialBackoffMs: 1000, BackoffMultiplier: 1.0, JitterFraction: 0.5}
+
+	for i := 0; i < 50; i++ {
+		got := backoffFor(nil, 0, policy)
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("backoffFor with 0.5 jitter fraction out of [500ms, 1500ms] bounds: %v", got)
+		}
+	}
+}
+
+func TestBackoffForHonorsRetryAfterSeconds(t *testing.T) {
+	policy := RetryPolicy{InitialBackoffMs: 100, BackoffMultiplier: 2.0}
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+	}
+
+	got := backoffFor(resp, 3, policy)
+	if got != 5*time.Second {
+		t.Errorf("backoffFor() with Retry-After header = %v, want %v", got, 5*time.Second)
+	}
+}
+
+func TestBackoffForHonorsRetryAfterHTTPDate(t *testing.T) {
+	policy := RetryPolicy{InitialBackoffMs: 100, BackoffMultiplier: 2.0}
+
+	future := time.Now().Add(10 * time.Second).UTC()
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{future.Format(http.TimeFormat)}},
+	}
+
+	got := backoffFor(resp, 0, policy)
+	if got <= 8*time.Second || got > 10*time.Second {
+		t.Errorf("backoffFor() with Retry-After HTTP-date = %v, want ~10s", got)
+	}
+}
+
+func TestBackoffForIgnoresRetryAfterOnNonRetryAfterStatus(t *testing.T) {
+	policy := RetryPolicy{InitialBackoffMs: 100, BackoffMultiplier: 2.0}
+
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+	}
+
+	got := backoffFor(resp, 0, policy)
+	if got != 100*time.Millisecond {
+		t.Errorf("backoffFor() on a 500 should ignore Retry-After, got %v, want %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestRetryAfterDuration(t *testing.T) {
+	twoSeconds := 2 * time.Second
+
+	tests := []struct {
+		name       string
+		statusCode int
+		header     string
+		wantOk     bool
+		want       *time.Duration
+	}{
+		{"no header", http.StatusTooManyRequests, "", false, nil},
+		{"non-retry-after status", http.StatusOK, "5", false, nil},
+		{"seconds form", http.StatusTooManyRequests, "2", true, &twoSeconds},
+		{"invalid value", http.StatusServiceUnavailable, "not-a-date", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.statusCode, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+
+			got, ok := retryAfterDuration(resp)
+			if ok != tt.wantOk {
+				t.Fatalf("retryAfterDuration() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if tt.want != nil && got != *tt.want {
+				t.Errorf("retryAfterDuration() = %v, want %v", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	codes := defaultRetryStatusCodes
+
+	for _, code := range codes {
+		if !isRetryableStatus(code, codes) {
+			t.Errorf("isRetryableStatus(%d) = false, want true", code)
+		}
+	}
+
+	if isRetryableStatus(http.StatusOK, codes) {
+		t.Errorf("isRetryableStatus(200) = true, want false")
+	}
+}
+
+func TestWithRetryDefaults(t *testing.T) {
+	policy := withRetryDefaults(RetryPolicy{})
+
+	if policy.BackoffMultiplier != 2.0 {
+		t.Errorf("BackoffMultiplier default = %v, want 2.0", policy.BackoffMultiplier)
+	}
+
+	if len(policy.RetryStatusCodes) != len(defaultRetryStatusCodes) {
+		t.Errorf("RetryStatusCodes default = %v, want %v", policy.RetryStatusCodes, defaultRetryStatusCodes)
+	}
+}
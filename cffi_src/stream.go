@@ -0,0 +1,104 @@
+package tls_client_cffi_src
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	"github.com/google/uuid"
+)
+
+// streamIdleTimeout closes and evicts a stream that has not been read from
+// in a while, so a caller that forgets to call CloseStream does not leak the
+// underlying response body / connection forever.
+const streamIdleTimeout = 5 * time.Minute
+
+const defaultStreamChunkSize = 32 * 1024
+
+type streamEntry struct {
+	resp  *http.Response
+	timer *time.Timer
+}
+
+var streamsLock = sync.Mutex{}
+var streams = make(map[string]*streamEntry)
+
+func registerStream(resp *http.Response) string {
+	streamId := uuid.New().String()
+
+	entry := &streamEntry{resp: resp}
+	entry.timer = time.AfterFunc(streamIdleTimeout, func() {
+		closeStreamEntry(streamId)
+	})
+
+	streamsLock.Lock()
+	streams[streamId] = entry
+	streamsLock.Unlock()
+
+	return streamId
+}
+
+// ReadFromStream reads up to chunkSize bytes off the response body
+// registered under streamId. The returned bool is true once the body has
+// been fully drained, at which point the stream is already closed and
+// removed from the registry.
+func ReadFromStream(streamId string, chunkSize int) ([]byte, bool, *TLSClientError) {
+	streamsLock.Lock()
+	entry, ok := streams[streamId]
+	streamsLock.Unlock()
+
+	if !ok {
+		return nil, false, NewTLSClientError(fmt.Errorf("stream %s not found", streamId))
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = defaultStreamChunkSize
+	}
+
+	buf := make([]byte, chunkSize)
+	n, err := entry.resp.Body.Read(buf)
+	entry.timer.Reset(streamIdleTimeout)
+
+	if err != nil && err != io.EOF {
+		closeStreamEntry(streamId)
+		return nil, false, NewTLSClientError(err)
+	}
+
+	if err == io.EOF {
+		closeStreamEntry(streamId)
+		return buf[:n], true, nil
+	}
+
+	return buf[:n], false, nil
+}
+
+// CloseStream releases the response body registered under streamId. If the
+// stream has already been drained or evicted by the idle timeout, nothing is
+// closed and a "not found" error is returned.
+func CloseStream(streamId string) *TLSClientError {
+	if !closeStreamEntry(streamId) {
+		return NewTLSClientError(fmt.Errorf("stream %s not found", streamId))
+	}
+
+	return nil
+}
+
+func closeStreamEntry(streamId string) bool {
+	streamsLock.Lock()
+	entry, ok := streams[streamId]
+	if ok {
+		delete(streams, streamId)
+	}
+	streamsLock.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	entry.timer.Stop()
+	entry.resp.Body.Close()
+
+	return true
+}